@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Коды завершения неинтерактивных подкоманд (set/get/unset/list/import/
+// diff). Разные причины отказа должны давать разные коды, чтобы
+// вызывающие скрипты могли различить их без парсинга текста ошибки.
+const (
+	exitGenericError     = 1 // usage-ошибка, ввод-вывод и т.п.
+	exitNotFound         = 2 // ключ не найден (get/unset)
+	exitAlreadyExists    = 3 // ключ уже существует без --force (set/import)
+	exitValidationFailed = 4 // ключ или значение не прошли схему
+)
+
+// cliError — ошибка подкоманды с привязанным кодом завершения. cmd*
+// функции возвращают *cliError там, где вызывающему скрипту важно
+// различить причину отказа; runCLICommand извлекает code через
+// errors.As, иначе использует exitGenericError.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error { return e.err }
+
+// newCLIError оборачивает fmt.Errorf(format, args...) с заданным кодом
+// завершения.
+func newCLIError(code int, format string, args ...any) *cliError {
+	return &cliError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// runCLICommand печатает ошибку err в stderr (если она не пустая) и
+// завершает процесс её кодом; при err == nil возвращается без действий,
+// оставляя процесс завершиться нулевым кодом естественным путём.
+func runCLICommand(err error) {
+	if err == nil {
+		return
+	}
+
+	var ce *cliError
+	if errors.As(err, &ce) {
+		if msg := ce.Error(); msg != "" {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		os.Exit(ce.code)
+	}
+
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(exitGenericError)
+}
+
+// reorderFlagsFirst переставляет args так, чтобы распознанные fs флаги
+// (и их значения) шли перед позиционными аргументами. Стандартный
+// flag.FlagSet прекращает разбор флагов на первом не-флаге, так что без
+// этого "envtool set KEY --value X" не сработал бы — только "envtool
+// set --value X KEY". "--" останавливает разбор флагов, как в getopt.
+func reorderFlagsFirst(fs *flag.FlagSet, args []string) []string {
+	var flags, positionals []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positionals = append(positionals, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+
+		name := strings.TrimLeft(arg, "-")
+		hasInlineValue := strings.Contains(name, "=")
+		if hasInlineValue {
+			name = name[:strings.Index(name, "=")]
+		}
+
+		isBool := false
+		if f := fs.Lookup(name); f != nil {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+				isBool = true
+			}
+		}
+		if !hasInlineValue && !isBool && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+
+	return append(flags, positionals...)
+}
+
+// lookupKey нормализует raw через activeKeySchema так же, как cmdSet
+// нормализует ключ перед записью, чтобы get/unset находили ключ по
+// тому же вводу, которым его создал set (например, с пробелами или в
+// нижнем регистре). Если raw не проходит текущую схему (например, в
+// файле остались ключи, записанные под другой схемой), используется
+// исходная строка как есть — get/unset должны суметь найти точное
+// совпадение, даже если оно больше не прошло бы валидацию заново.
+func lookupKey(raw string) string {
+	if formatted, err := activeKeySchema.Format(raw); err == nil {
+		return formatted
+	}
+	return raw
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdSetGetUnsetExitCodes(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+
+	if err := cmdSet([]string{"--file", envPath, "DATABASE_URL=postgres://localhost/db"}); err != nil {
+		t.Fatalf("cmdSet: %v", err)
+	}
+
+	err := cmdSet([]string{"--file", envPath, "DATABASE_URL=other"})
+	assertCLIErrorCode(t, err, exitAlreadyExists)
+
+	if err := cmdSet([]string{"--file", envPath, "--force", "DATABASE_URL=other"}); err != nil {
+		t.Fatalf("cmdSet --force: %v", err)
+	}
+
+	err = cmdSet([]string{"--file", envPath, "2FA=x"})
+	assertCLIErrorCode(t, err, exitValidationFailed)
+
+	if err := cmdUnset([]string{"--file", envPath, "DATABASE_URL"}); err != nil {
+		t.Fatalf("cmdUnset: %v", err)
+	}
+
+	err = cmdUnset([]string{"--file", envPath, "DATABASE_URL"})
+	assertCLIErrorCode(t, err, exitNotFound)
+}
+
+func TestCmdGetNormalizesKeyLikeCmdSet(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+
+	if err := cmdSet([]string{"--file", envPath, "db url=postgres://x"}); err != nil {
+		t.Fatalf("cmdSet: %v", err)
+	}
+
+	if err := cmdGet([]string{"--file", envPath, "db url"}); err != nil {
+		t.Fatalf("cmdGet with the same raw key cmdSet normalized should find it: %v", err)
+	}
+}
+
+func TestCmdSetValueFlagAcceptsExplicitEmptyString(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+
+	if err := cmdSet([]string{"--file", envPath, "--value", "", "EMPTY_VAR"}); err != nil {
+		t.Fatalf("cmdSet --value '' should set an empty value, not fall through to stdin: %v", err)
+	}
+}
+
+func TestCmdDiffMissingFileIsNotFoundNotEmpty(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.env")
+	missing := filepath.Join(dir, "b.env")
+
+	if err := cmdSet([]string{"--file", a, "FOO=bar"}); err != nil {
+		t.Fatalf("cmdSet: %v", err)
+	}
+
+	err := cmdDiff([]string{a, missing})
+	assertCLIErrorCode(t, err, exitNotFound)
+
+	if _, statErr := os.Stat(missing); !os.IsNotExist(statErr) {
+		t.Fatalf("cmdDiff must not create the missing file, got stat err %v", statErr)
+	}
+}
+
+func TestCmdImportMissingFileIsNotFoundNotEmpty(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, ".env")
+	missing := filepath.Join(dir, "source.env")
+
+	err := cmdImport([]string{"--file", target, missing})
+	assertCLIErrorCode(t, err, exitNotFound)
+
+	if _, statErr := os.Stat(missing); !os.IsNotExist(statErr) {
+		t.Fatalf("cmdImport must not create the missing source file, got stat err %v", statErr)
+	}
+}
+
+func TestCmdGetListUnsetDoNotCreateMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "typo.env")
+
+	assertCLIErrorCode(t, cmdGet([]string{"--file", missing, "KEY"}), exitNotFound)
+	assertCLIErrorCode(t, cmdList([]string{"--file", missing}), exitNotFound)
+	assertCLIErrorCode(t, cmdUnset([]string{"--file", missing, "KEY"}), exitNotFound)
+
+	if _, statErr := os.Stat(missing); !os.IsNotExist(statErr) {
+		t.Fatalf("get/list/unset must not create the missing file, got stat err %v", statErr)
+	}
+}
+
+func assertCLIErrorCode(t *testing.T, err error, wantCode int) {
+	t.Helper()
+	var ce *cliError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected *cliError, got %v (%T)", err, err)
+	}
+	if ce.code != wantCode {
+		t.Fatalf("got exit code %d, want %d", ce.code, wantCode)
+	}
+}
+
+func TestReorderFlagsFirstAllowsFlagsAfterPositional(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("force", false, "")
+	fs.String("value", "", "")
+
+	got := reorderFlagsFirst(fs, []string{"KEY", "--force", "--value", "x"})
+	want := []string{"--force", "--value", "x", "KEY"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// runInteractiveAdd — исходный интерактивный сценарий добавления
+// переменной, теперь доступный как "envtool add" (и как поведение по
+// умолчанию без аргументов). В отличие от остальных подкоманд, он
+// рассчитан на запуск человеком в терминале: эмодзи, подсказки и
+// подтверждения на русском.
+func runInteractiveAdd() {
+	fmt.Println("🔐 Безопасное добавление переменной в .env")
+
+	reader := bufio.NewReader(os.Stdin)
+
+	// Получаем оригинальный ввод (до форматирования)
+	originalKeyInput := promptValidInput(reader, "Введите ключ переменной (например: db password): ", func(s string) (string, error) {
+		return s, nil
+	})
+
+	// Форматируем ключ отдельно
+	formattedKey, err := formatAndValidateKey(originalKeyInput)
+	if err != nil {
+		fmt.Println("❌ Ошибка:", err)
+		return
+	}
+
+	value, err := promptValidSecret(reader, "Введите значение переменной: ", validateValue)
+	if err != nil {
+		fmt.Println("❌ Ошибка чтения значения:", err)
+		return
+	}
+
+	added, updated, err := AddOrUpdateEnvVarSecure(reader, ".env", originalKeyInput, formattedKey, value)
+	if err != nil {
+		fmt.Println("❌ Ошибка при обновлении:", err)
+		return
+	}
+
+	if added {
+		fmt.Println("✅ Переменная успешно добавлена.")
+	} else if updated {
+		fmt.Println("✅ Переменная успешно обновлена.")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Je-double-f/ForTest/internal/envparse"
+)
+
+// cmdDiff реализует "envtool diff a.env b.env": сравнивает ключи двух
+// .env-файлов и печатает различия построчно ("-" — только в a, "+" —
+// только в b или изменившееся значение), отсортированные по ключу.
+// Как и diff(1), завершается ненулевым кодом, если файлы различаются.
+// Отсутствующий a.env/b.env — exitNotFound, а не пустой файл: опечатка в
+// пути не должна молча выглядеть как "все ключи добавлены".
+func cmdDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return newCLIError(exitGenericError, "использование: envtool diff a.env b.env")
+	}
+
+	a, err := loadExistingEnvFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := loadExistingEnvFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	aMap, bMap := a.Map(), b.Map()
+
+	keySet := make(map[string]struct{}, len(aMap)+len(bMap))
+	for k := range aMap {
+		keySet[k] = struct{}{}
+	}
+	for k := range bMap {
+		keySet[k] = struct{}{}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	differs := false
+	for _, k := range keys {
+		aVal, aOk := aMap[k]
+		bVal, bOk := bMap[k]
+		switch {
+		case aOk && !bOk:
+			differs = true
+			fmt.Printf("-%s=%s\n", k, aVal)
+		case !aOk && bOk:
+			differs = true
+			fmt.Printf("+%s=%s\n", k, bVal)
+		case aVal != bVal:
+			differs = true
+			fmt.Printf("-%s=%s\n+%s=%s\n", k, aVal, k, bVal)
+		}
+	}
+
+	if differs {
+		return &cliError{code: exitGenericError}
+	}
+	return nil
+}
+
+// loadExistingEnvFile читает filePath через envparse.LoadExistingEnvFile,
+// оборачивая отсутствующий файл в exitNotFound вместо того, чтобы молча
+// принять опечатку в пути за пустой .env (в отличие от --file цели
+// set/unset/add, источник diff/import обязан существовать).
+func loadExistingEnvFile(filePath string) (*envparse.Document, error) {
+	doc, err := envparse.LoadExistingEnvFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, newCLIError(exitNotFound, "файл %s не найден", filePath)
+		}
+		return nil, err
+	}
+	return doc, nil
+}
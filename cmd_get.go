@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// cmdGet реализует "envtool get KEY [--file .env]": печатает значение в
+// stdout без какого-либо оформления, чтобы результат был пригоден для
+// прямой подстановки в shell-пайплайн. Отсутствующий ключ, как и
+// отсутствующий --file, завершает команду exitNotFound — get только
+// читает .env и не создаёт его заново.
+func cmdGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	file := fs.String("file", ".env", "путь к .env-файлу")
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newCLIError(exitGenericError, "использование: envtool get KEY [--file .env]")
+	}
+	key := lookupKey(fs.Arg(0))
+
+	doc, _, err := loadEnvReadOnly(bufio.NewReader(os.Stdin), *file)
+	if err != nil {
+		return err
+	}
+
+	value, ok := doc.Get(key)
+	if !ok {
+		return newCLIError(exitNotFound, "ключ %s не найден в %s", key, *file)
+	}
+
+	fmt.Println(value)
+	return nil
+}
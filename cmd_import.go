@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+
+	"github.com/Je-double-f/ForTest/internal/filelock"
+)
+
+// cmdImport реализует "envtool import <file> [--file .env] [--force]":
+// переносит все ключи source в целевой .env, прогоняя каждый ключ и
+// значение через activeKeySchema/activeValueSchema. Существующий ключ с
+// другим значением без --force завершает команду exitAlreadyExists;
+// ключ или значение, не прошедшие схему, — exitValidationFailed. Source,
+// в отличие от --file цели, не создаётся заново: отсутствующий file —
+// exitNotFound.
+func cmdImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	target := fs.String("file", ".env", "путь к целевому .env-файлу")
+	force := fs.Bool("force", false, "перезаписывать существующие ключи с другим значением")
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newCLIError(exitGenericError, "использование: envtool import <file> [--file .env] [--force]")
+	}
+
+	source, err := loadExistingEnvFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	lock, err := filelock.TryLock(*target + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	doc, passphrase, err := loadEnv(bufio.NewReader(os.Stdin), *target)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range source.Keys() {
+		value, _ := source.Get(key)
+
+		formattedKey, err := activeKeySchema.Format(key)
+		if err != nil {
+			return newCLIError(exitValidationFailed, "ключ %s: %w", key, err)
+		}
+		validatedValue, err := activeValueSchema.Validate(value)
+		if err != nil {
+			return newCLIError(exitValidationFailed, "ключ %s: %w", formattedKey, err)
+		}
+
+		if existing, exists := doc.Get(formattedKey); exists && existing != validatedValue && !*force {
+			return newCLIError(exitAlreadyExists, "ключ %s уже существует в %s с другим значением (используйте --force)", formattedKey, *target)
+		}
+		doc.Set(formattedKey, validatedValue)
+	}
+
+	return saveEnv(doc, *target, passphrase)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cmdList реализует "envtool list [--file .env] [--format=env|json|shell]".
+// Отсутствующий --file завершает команду exitNotFound — list только
+// читает .env и не создаёт его заново.
+func cmdList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	file := fs.String("file", ".env", "путь к .env-файлу")
+	format := fs.String("format", "env", "формат вывода: env, json или shell")
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return newCLIError(exitGenericError, "использование: envtool list [--file .env] [--format=env|json|shell]")
+	}
+
+	doc, _, err := loadEnvReadOnly(bufio.NewReader(os.Stdin), *file)
+	if err != nil {
+		return err
+	}
+
+	keys := doc.Keys()
+	values := doc.Map()
+
+	switch *format {
+	case "env":
+		for _, k := range keys {
+			fmt.Printf("%s=%s\n", k, values[k])
+		}
+	case "shell":
+		for _, k := range keys {
+			fmt.Printf("export %s=%s\n", k, shellQuote(values[k]))
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		// map[string]string сериализуется с ключами в алфавитном порядке;
+		// порядок файла здесь менее важен, чем в env/shell-выводе.
+		if err := enc.Encode(values); err != nil {
+			return err
+		}
+	default:
+		return newCLIError(exitGenericError, "неизвестный формат %q (допустимо: env, json, shell)", *format)
+	}
+	return nil
+}
+
+// shellQuote оборачивает value в одинарные кавычки по правилам POSIX
+// shell (экранируя "'" как "'\”"), чтобы export-строка была безопасна
+// для eval.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/Je-double-f/ForTest/internal/filelock"
+)
+
+// cmdSet реализует "envtool set KEY[=VALUE] [--file .env] [--force]" —
+// неинтерактивный аналог AddOrUpdateEnvVarSecure для скриптов и CI.
+// Значение берётся в порядке приоритета: из "KEY=VALUE", из --value, из
+// содержимого --from-file, а если ничего из этого не задано — из
+// единственной строки, прочитанной из stdin (для `echo secret | envtool
+// set KEY`). Существующий ключ без --force завершает команду
+// exitAlreadyExists; невалидные ключ или значение — exitValidationFailed.
+func cmdSet(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	file := fs.String("file", ".env", "путь к .env-файлу")
+	force := fs.Bool("force", false, "перезаписать существующий ключ без ошибки")
+	value := fs.String("value", "", "значение переменной (альтернатива KEY=VALUE и --from-file)")
+	fromFile := fs.String("from-file", "", "прочитать значение из содержимого указанного файла")
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newCLIError(exitGenericError, "использование: envtool set KEY[=VALUE] [--file .env] [--force]")
+	}
+
+	rawKey, inlineValue, hasInline := strings.Cut(fs.Arg(0), "=")
+
+	valueProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "value" {
+			valueProvided = true
+		}
+	})
+
+	resolvedValue, err := resolveSetValue(inlineValue, hasInline, *value, valueProvided, *fromFile)
+	if err != nil {
+		return err
+	}
+
+	formattedKey, err := activeKeySchema.Format(rawKey)
+	if err != nil {
+		return newCLIError(exitValidationFailed, "ключ: %w", err)
+	}
+	validatedValue, err := activeValueSchema.Validate(resolvedValue)
+	if err != nil {
+		return newCLIError(exitValidationFailed, "значение: %w", err)
+	}
+
+	lock, err := filelock.TryLock(*file + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	doc, passphrase, err := loadEnv(bufio.NewReader(os.Stdin), *file)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := doc.Get(formattedKey); exists && !*force {
+		return newCLIError(exitAlreadyExists, "ключ %s уже существует в %s (используйте --force для перезаписи)", formattedKey, *file)
+	}
+
+	doc.Set(formattedKey, validatedValue)
+	return saveEnv(doc, *file, passphrase)
+}
+
+// resolveSetValue выбирает значение из KEY=VALUE, --value (включая явно
+// переданную пустую строку), --from-file или, если ничего не задано, из
+// первой строки stdin.
+func resolveSetValue(inlineValue string, hasInline bool, flagValue string, valueProvided bool, fromFile string) (string, error) {
+	switch {
+	case hasInline:
+		return inlineValue, nil
+	case valueProvided:
+		return flagValue, nil
+	case fromFile != "":
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", newCLIError(exitGenericError, "значение не передано: укажите KEY=VALUE, --value, --from-file или подайте его через stdin")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+
+	"github.com/Je-double-f/ForTest/internal/filelock"
+)
+
+// cmdUnset реализует "envtool unset KEY [--file .env]": удаляет строку
+// ключа, сохраняя порядок, комментарии и пустые строки остального
+// файла. Отсутствующий ключ, как и отсутствующий --file, завершает
+// команду exitNotFound — unset не создаёт .env, которого не было.
+func cmdUnset(args []string) error {
+	fs := flag.NewFlagSet("unset", flag.ContinueOnError)
+	file := fs.String("file", ".env", "путь к .env-файлу")
+	if err := fs.Parse(reorderFlagsFirst(fs, args)); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newCLIError(exitGenericError, "использование: envtool unset KEY [--file .env]")
+	}
+	key := lookupKey(fs.Arg(0))
+
+	lock, err := filelock.TryLock(*file + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	doc, passphrase, err := loadEnvReadOnly(bufio.NewReader(os.Stdin), *file)
+	if err != nil {
+		return err
+	}
+
+	if !doc.Delete(key) {
+		return newCLIError(exitNotFound, "ключ %s не найден в %s", key, *file)
+	}
+
+	return saveEnv(doc, *file, passphrase)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/Je-double-f/ForTest/internal/envcrypt"
+	"github.com/Je-double-f/ForTest/internal/envparse"
+	"github.com/Je-double-f/ForTest/internal/filelock"
+)
+
+// runCryptoSubcommand обрабатывает "--encrypt"/"--decrypt"/"--rekey" —
+// команды для перевода .env между открытой и зашифрованной формой,
+// вызываемые до основного интерактивного сценария add. Все подсказки
+// внутри одного вызова читают из общего reader (см. promptSecret) —
+// так пайп с несколькими строками (например, кодовой фразой и её
+// подтверждением) разбирается по одной строке за раз, а не теряется,
+// когда под каждую подсказку заводится новый bufio.Reader.
+func runCryptoSubcommand(arg, filePath string) error {
+	reader := bufio.NewReader(os.Stdin)
+	switch arg {
+	case "--encrypt":
+		return runEncryptCommand(reader, filePath)
+	case "--decrypt":
+		return runDecryptCommand(reader, filePath)
+	case "--rekey":
+		return runRekeyCommand(reader, filePath)
+	default:
+		return fmt.Errorf("неизвестный аргумент %q (допустимо: --encrypt, --decrypt, --rekey)", arg)
+	}
+}
+
+// runEncryptCommand шифрует открытый filePath на месте: запрашивает
+// новую кодовую фразу (с подтверждением) и перезаписывает файл
+// запечатанной версией. Запись выполняется под блокировкой
+// "<filePath>.lock" и атомарно, с резервной копией (см. internal/envparse
+// и internal/filelock), как и остальные команды, изменяющие .env.
+func runEncryptCommand(reader *bufio.Reader, filePath string) error {
+	lock, err := filelock.TryLock(filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if envcrypt.IsEncrypted(data) {
+		return fmt.Errorf("файл %s уже зашифрован", filePath)
+	}
+
+	passphrase, err := promptSecretConfirm(
+		reader,
+		"Введите кодовую фразу для шифрования: ",
+		"Повторите кодовую фразу: ",
+	)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := envcrypt.Seal(data, passphrase, envcrypt.DefaultKDFParams)
+	if err != nil {
+		return err
+	}
+	if err := envparse.WriteFileAtomic(filePath, sealed, true); err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Файл зашифрован.")
+	return nil
+}
+
+// runDecryptCommand расшифровывает зашифрованный filePath на месте,
+// запрашивая текущую кодовую фразу. Запись, как и в runEncryptCommand,
+// выполняется под блокировкой и атомарно, с резервной копией.
+func runDecryptCommand(reader *bufio.Reader, filePath string) error {
+	lock, err := filelock.TryLock(filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if !envcrypt.IsEncrypted(data) {
+		return fmt.Errorf("файл %s не зашифрован", filePath)
+	}
+
+	passphrase, err := promptSecret(reader, "Введите кодовую фразу: ")
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := envcrypt.Open(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := envparse.WriteFileAtomic(filePath, plaintext, true); err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Файл расшифрован.")
+	return nil
+}
+
+// runRekeyCommand меняет кодовую фразу зашифрованного filePath: снимает
+// старое шифрование, запрашивает новую фразу (с подтверждением) и
+// перешифровывает данные свежей солью и nonce. Перезапись файла, как и в
+// runEncryptCommand, атомарна, под блокировкой и с резервной копией.
+func runRekeyCommand(reader *bufio.Reader, filePath string) error {
+	lock, err := filelock.TryLock(filePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if !envcrypt.IsEncrypted(data) {
+		return fmt.Errorf("файл %s не зашифрован", filePath)
+	}
+
+	oldPassphrase, err := promptSecret(reader, "Текущая кодовая фраза: ")
+	if err != nil {
+		return err
+	}
+	newPassphrase, err := promptSecretConfirm(
+		reader,
+		"Новая кодовая фраза: ",
+		"Повторите новую кодовую фразу: ",
+	)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := envcrypt.Rekey(data, oldPassphrase, newPassphrase, envcrypt.DefaultKDFParams)
+	if err != nil {
+		return err
+	}
+	if err := envparse.WriteFileAtomic(filePath, sealed, true); err != nil {
+		return err
+	}
+
+	fmt.Println("✅ Кодовая фраза обновлена.")
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Je-double-f/ForTest/internal/envcrypt"
+)
+
+func TestRunEncryptCommandAcceptsPipedPassphraseConfirmation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("FOO=bar\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	reader := withPipedStdin(t, "pass1234\npass1234\n")
+
+	if err := runEncryptCommand(reader, path); err != nil {
+		t.Fatalf("runEncryptCommand: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+	if !envcrypt.IsEncrypted(data) {
+		t.Fatalf("file was not encrypted")
+	}
+}
+
+func TestRunRekeyCommandAcceptsPipedMultiLinePassphrases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	sealed, err := envcrypt.Seal([]byte("FOO=bar\n"), "oldpass1", envcrypt.DefaultKDFParams)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := os.WriteFile(path, sealed, 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	reader := withPipedStdin(t, "oldpass1\nnewpass2\nnewpass2\n")
+
+	if err := runRekeyCommand(reader, path); err != nil {
+		t.Fatalf("runRekeyCommand: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+	plaintext, err := envcrypt.Open(data, "newpass2")
+	if err != nil {
+		t.Fatalf("file was not rekeyed to the new passphrase: %v", err)
+	}
+	if string(plaintext) != "FOO=bar\n" {
+		t.Fatalf("got plaintext %q, want %q", plaintext, "FOO=bar\n")
+	}
+}
+
+// withPipedStdin redirects os.Stdin to a pipe preloaded with input
+// (restored via t.Cleanup) and returns a bufio.Reader over that same
+// pipe — mirroring a real piped invocation, where promptSecret's
+// non-TTY fallback and the reader it consumes from must be the same
+// stdin.
+func withPipedStdin(t *testing.T, input string) *bufio.Reader {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatalf("write stdin fixture: %v", err)
+	}
+	w.Close()
+
+	original := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = original })
+
+	return bufio.NewReader(r)
+}
@@ -0,0 +1,162 @@
+// Package envcrypt реализует шифрование .env-файлов "at rest":
+// содержимое запечатывается AES-256-GCM с ключом, полученным из
+// пользовательской кодовой фразы через Argon2id. Формат файла — заголовок
+// (magic, версия, параметры KDF, соль, nonce), за которым следует
+// шифротекст.
+package envcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	magic     = "ENVC"
+	version   = 1
+	saltSize  = 16
+	nonceSize = 12
+	keySize   = 32
+
+	headerSize = len(magic) + 1 + 4 + 4 + 1 + saltSize + nonceSize
+)
+
+// KDFParams — параметры Argon2id, сохраняемые в заголовке каждого
+// зашифрованного файла. Храня их рядом с данными, можно со временем
+// повышать стоимость KDF для новых файлов, не теряя способность
+// расшифровать файлы, запечатанные со старыми параметрами.
+type KDFParams struct {
+	Time    uint32 // число итераций
+	Memory  uint32 // объём памяти, КиБ
+	Threads uint8
+}
+
+// DefaultKDFParams — параметры, применяемые при запечатывании новых файлов.
+var DefaultKDFParams = KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+func deriveKey(passphrase string, salt []byte, p KDFParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, keySize)
+}
+
+// IsEncrypted сообщает, начинается ли data с магической
+// последовательности формата envcrypt.
+func IsEncrypted(data []byte) bool {
+	return len(data) >= len(magic) && string(data[:len(magic)]) == magic
+}
+
+// Seal шифрует plaintext кодовой фразой passphrase, используя свежую
+// случайную соль и nonce, и возвращает файл целиком: заголовок
+// (magic || version || параметры KDF || соль || nonce) плюс
+// AES-256-GCM-шифротекст с AAD на заголовке.
+func Seal(plaintext []byte, passphrase string, params KDFParams) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("envcrypt: не удалось сгенерировать соль: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("envcrypt: не удалось сгенерировать nonce: %w", err)
+	}
+
+	header := encodeHeader(params, salt, nonce)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, header)
+
+	return append(header, ciphertext...), nil
+}
+
+// Open проверяет заголовок, заново вычисляет ключ по сохранённым в нём
+// параметрам KDF и расшифровывает payload. Возвращает ошибку, если
+// кодовая фраза неверна или файл повреждён (не проходит аутентификация GCM).
+func Open(data []byte, passphrase string) ([]byte, error) {
+	params, salt, nonce, header, body, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, body, header)
+	if err != nil {
+		return nil, errors.New("envcrypt: неверная кодовая фраза или повреждённый файл")
+	}
+	return plaintext, nil
+}
+
+// Rekey расшифровывает data старой кодовой фразой и заново запечатывает
+// результат новой кодовой фразой со свежей солью и nonce (и, при
+// необходимости, обновлёнными параметрами KDF), так что на диске не
+// остаётся данных, зашифрованных старым ключом.
+func Rekey(data []byte, oldPassphrase, newPassphrase string, params KDFParams) ([]byte, error) {
+	plaintext, err := Open(data, oldPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	return Seal(plaintext, newPassphrase, params)
+}
+
+func newGCM(passphrase string, salt []byte, params KDFParams) (cipher.AEAD, error) {
+	key := deriveKey(passphrase, salt, params)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encodeHeader(p KDFParams, salt, nonce []byte) []byte {
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint32(header, p.Time)
+	header = binary.BigEndian.AppendUint32(header, p.Memory)
+	header = append(header, p.Threads)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+	return header
+}
+
+// decodeHeader разбирает заголовок файла и возвращает параметры KDF,
+// соль, nonce, сам заголовок (как AAD) и оставшийся шифротекст.
+func decodeHeader(data []byte) (params KDFParams, salt, nonce, header, body []byte, err error) {
+	if len(data) < headerSize {
+		return KDFParams{}, nil, nil, nil, nil, errors.New("envcrypt: файл повреждён или слишком короткий")
+	}
+	if string(data[:len(magic)]) != magic {
+		return KDFParams{}, nil, nil, nil, nil, errors.New("envcrypt: неизвестный формат файла (нет магической последовательности)")
+	}
+
+	pos := len(magic)
+	ver := data[pos]
+	pos++
+	if ver != version {
+		return KDFParams{}, nil, nil, nil, nil, fmt.Errorf("envcrypt: неподдерживаемая версия формата: %d", ver)
+	}
+
+	params = KDFParams{
+		Time:    binary.BigEndian.Uint32(data[pos : pos+4]),
+		Memory:  binary.BigEndian.Uint32(data[pos+4 : pos+8]),
+		Threads: data[pos+8],
+	}
+	pos += 9
+
+	salt = data[pos : pos+saltSize]
+	pos += saltSize
+	nonce = data[pos : pos+nonceSize]
+	pos += nonceSize
+
+	return params, salt, nonce, data[:pos], data[pos:], nil
+}
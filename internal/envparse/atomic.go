@@ -0,0 +1,53 @@
+package envparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic записывает data в path так, чтобы конкурентное чтение
+// или аварийное завершение процесса посередине записи никогда не видело
+// наполовину записанный файл: data пишется и fsync'ится во временный
+// файл ".<имя>.<pid>.tmp" в той же директории, после чего временный файл
+// переименовывается поверх path (rename на одной файловой системе
+// атомарен). Если keepBackup истинен и path уже существует, его текущее
+// содержимое предварительно сохраняется в "<path>.bak" — лучшее
+// доступное состояние на случай, если новая запись окажется ошибочной.
+func WriteFileAtomic(path string, data []byte, keepBackup bool) error {
+	dir := filepath.Dir(path)
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", filepath.Base(path), os.Getpid()))
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("envparse: не удалось создать временный файл: %w", err)
+	}
+	defer os.Remove(tmpPath) // не удаляет переименованный файл; нет ошибки, если его уже нет
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("envparse: не удалось записать временный файл: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("envparse: не удалось сбросить временный файл на диск: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("envparse: не удалось закрыть временный файл: %w", err)
+	}
+
+	if keepBackup {
+		if previous, err := os.ReadFile(path); err == nil {
+			if err := os.WriteFile(path+".bak", previous, 0600); err != nil {
+				return fmt.Errorf("envparse: не удалось сохранить резервную копию: %w", err)
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("envparse: не удалось прочитать предыдущее содержимое для резервной копии: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("envparse: не удалось переименовать временный файл поверх %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package envparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomicReplacesContentAndKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+
+	if err := os.WriteFile(path, []byte("FOO=old\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("FOO=new\n"), true); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+	if string(got) != "FOO=new\n" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if string(backup) != "FOO=old\n" {
+		t.Fatalf("unexpected backup content: %q", backup)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".tmp" {
+			t.Fatalf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicWithoutBackupSkipsBakFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	if err := os.WriteFile(path, []byte("FOO=old\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("FOO=new\n"), false); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup file, stat err = %v", err)
+	}
+}
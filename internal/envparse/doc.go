@@ -0,0 +1,318 @@
+package envparse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LineKind различает виды строк, которые Document хранит в порядке их
+// появления в файле.
+type LineKind int
+
+const (
+	KindBlank LineKind = iota
+	KindComment
+	KindKV
+)
+
+// Line — одна строка .env-файла в исходном порядке. Для KindBlank и
+// KindComment используется только Raw. Для KindKV Raw хранит исходный
+// текст (включая исходные физические строки для многострочных значений)
+// и используется при записи без изменений; если значение было изменено
+// через Document.Set, строка форматируется заново из Key/Value, сохраняя
+// ExportPrefix и Trailing исходной строки.
+type Line struct {
+	Kind         LineKind
+	Raw          string
+	Key          string
+	Value        string
+	Quote        byte // 0, '\'' или '"' — стиль кавычек исходной строки
+	ExportPrefix string
+	Trailing     string // текст после значения (инлайн-комментарий и пробелы вокруг него)
+	dirty        bool
+	fresh        bool // строка добавлена через Set, а не прочитана из исходного файла
+}
+
+// Document — разобранный .env-файл, сохраняющий порядок строк,
+// комментарии и пустые строки, чтобы повторная запись не переставляла
+// ничего, кроме изменённых ключей.
+type Document struct {
+	lines           []*Line
+	index           map[string]int
+	trailingNewline bool // исходный файл заканчивался символом "\n"
+}
+
+// LoadEnvFile читает .env-файл (создавая его, если он не существует) в
+// Document, сохраняющий порядок строк, комментарии, пустые строки и
+// исходное оформление значений. Предназначена для целевого файла команд,
+// которые вправе завести новый .env (set/unset/add) — для файла, который
+// только читается, используйте LoadExistingEnvFile, иначе опечатка в
+// пути будет молча принята за пустой файл.
+func LoadEnvFile(filePath string) (*Document, error) {
+	file, err := os.OpenFile(filePath, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return LoadEnvBytes(data)
+}
+
+// LoadExistingEnvFile — как LoadEnvFile, но не создаёт filePath: если
+// файла нет, возвращается обычная ошибка отсутствующего файла (проверяемая
+// через os.IsNotExist), а не пустой Document. Используется там, где
+// filePath — источник, который должен существовать (diff, import), а не
+// цель, которую можно завести с нуля.
+func LoadExistingEnvFile(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return LoadEnvBytes(data)
+}
+
+// LoadEnvBytes разбирает уже прочитанное содержимое .env-файла в
+// Document. Используется LoadEnvFile и вызывающим кодом, которому нужно
+// разобрать данные, полученные не из файла напрямую — например,
+// расшифрованные в памяти envcrypt.
+func LoadEnvBytes(data []byte) (*Document, error) {
+	doc := &Document{index: make(map[string]int), trailingNewline: bytes.HasSuffix(data, []byte("\n"))}
+	env := make(map[string]string) // для интерполяции ${VAR} по уже разобранным ключам
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		rawLine := scanner.Text()
+		trimmed := strings.TrimSpace(rawLine)
+
+		switch {
+		case trimmed == "":
+			doc.append(&Line{Kind: KindBlank, Raw: rawLine})
+		case strings.HasPrefix(trimmed, "#"):
+			doc.append(&Line{Kind: KindComment, Raw: rawLine})
+		default:
+			line, err := parseKVLine(rawLine, scanner, &lineNum, env)
+			if err != nil {
+				return nil, fmt.Errorf("envparse: строка %d: %w", lineNum, err)
+			}
+			env[line.Key] = line.Value
+			doc.append(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parseKVLine разбирает строку вида "[export ]KEY=VALUE[ #comment]",
+// дочитывая последующие физические строки, если значение в кавычках не
+// закрылось на этой строке, и возвращает Line с исходным Raw-текстом.
+func parseKVLine(rawLine string, scanner *bufio.Scanner, lineNum *int, env map[string]string) (*Line, error) {
+	physical := []string{rawLine}
+
+	idx := strings.Index(rawLine, "=")
+	if idx < 0 {
+		return nil, fmt.Errorf("отсутствует \"=\"")
+	}
+	keyPart := strings.TrimSpace(rawLine[:idx])
+	afterEq := rawLine[idx+1:]
+
+	exportPrefix := ""
+	switch {
+	case strings.HasPrefix(keyPart, "export "):
+		exportPrefix = "export "
+		keyPart = strings.TrimSpace(strings.TrimPrefix(keyPart, "export "))
+	case strings.HasPrefix(keyPart, "export\t"):
+		exportPrefix = "export\t"
+		keyPart = strings.TrimSpace(strings.TrimPrefix(keyPart, "export\t"))
+	}
+	if keyPart == "" {
+		return nil, fmt.Errorf("пустой ключ")
+	}
+
+	valuePart := strings.TrimLeft(afterEq, " \t")
+
+	var (
+		quote    byte
+		value    string
+		trailing string
+		err      error
+	)
+	switch {
+	case len(valuePart) > 0 && (valuePart[0] == '"' || valuePart[0] == '\''):
+		quote = valuePart[0]
+		var quotedEnv map[string]string
+		if quote == '"' {
+			quotedEnv = env
+		}
+		value, trailing, err = readQuoted(valuePart[1:], scanner, lineNum, quote, quotedEnv, &physical)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		value, trailing = splitTrailingComment(valuePart)
+	}
+
+	return &Line{
+		Kind:         KindKV,
+		Raw:          strings.Join(physical, "\n"),
+		Key:          keyPart,
+		Value:        value,
+		Quote:        quote,
+		ExportPrefix: exportPrefix,
+		Trailing:     trailing,
+	}, nil
+}
+
+// splitTrailingComment отделяет незаключённое в кавычки значение от
+// инлайн-комментария, возвращая обрезанное значение и исходный (вместе
+// с пробелами) хвост строки.
+func splitTrailingComment(s string) (value, trailing string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimRight(s[:i], " \t"), s[i:]
+		}
+	}
+	return strings.TrimRight(s, " \t"), ""
+}
+
+func (d *Document) append(l *Line) {
+	if l.Kind == KindKV {
+		d.index[l.Key] = len(d.lines)
+	}
+	d.lines = append(d.lines, l)
+}
+
+// Get возвращает значение ключа и признак его наличия.
+func (d *Document) Get(key string) (string, bool) {
+	idx, ok := d.index[key]
+	if !ok {
+		return "", false
+	}
+	return d.lines[idx].Value, true
+}
+
+// Set добавляет новый ключ или обновляет значение существующего,
+// сообщая, был ли ключ добавлен заново (added == false — значит,
+// обновлён существующий). Новые ключи дописываются в конец файла,
+// перед завершающей пустой строкой, если она есть, чтобы не сливаться с
+// предыдущим блоком.
+func (d *Document) Set(key, value string) (added bool) {
+	if idx, ok := d.index[key]; ok {
+		d.lines[idx].Value = value
+		d.lines[idx].dirty = true
+		return false
+	}
+
+	line := &Line{Kind: KindKV, Key: key, Value: value, dirty: true, fresh: true}
+
+	insertAt := len(d.lines)
+	if insertAt > 0 && d.lines[insertAt-1].Kind == KindBlank {
+		insertAt--
+	}
+
+	d.lines = append(d.lines, nil)
+	copy(d.lines[insertAt+1:], d.lines[insertAt:])
+	d.lines[insertAt] = line
+
+	for k, i := range d.index {
+		if i >= insertAt {
+			d.index[k] = i + 1
+		}
+	}
+	d.index[key] = insertAt
+	return true
+}
+
+// Delete удаляет строку key, если она существует, сообщая, был ли
+// ключ найден. Остальные строки (включая окружающие комментарии и
+// пустые строки) сохраняют свой порядок.
+func (d *Document) Delete(key string) (removed bool) {
+	idx, ok := d.index[key]
+	if !ok {
+		return false
+	}
+
+	d.lines = append(d.lines[:idx], d.lines[idx+1:]...)
+	delete(d.index, key)
+	for k, i := range d.index {
+		if i > idx {
+			d.index[k] = i - 1
+		}
+	}
+	return true
+}
+
+// Keys возвращает ключи в порядке их появления в файле.
+func (d *Document) Keys() []string {
+	keys := make([]string, 0, len(d.index))
+	for _, l := range d.lines {
+		if l.Kind == KindKV {
+			keys = append(keys, l.Key)
+		}
+	}
+	return keys
+}
+
+// Map возвращает значения Document в виде обычной карты ключ-значение,
+// для кода, которому не нужен порядок строк.
+func (d *Document) Map() map[string]string {
+	out := make(map[string]string, len(d.index))
+	for _, l := range d.lines {
+		if l.Kind == KindKV {
+			out[l.Key] = l.Value
+		}
+	}
+	return out
+}
+
+// Bytes отрисовывает Document обратно в текст .env: строки, не
+// затронутые Set, выводятся байт-в-байт как в исходном файле; изменённые
+// KV-строки переформатируются из Key/Value с сохранением ExportPrefix и
+// Trailing. Завершающий перенос строки добавляется после каждой строки,
+// кроме последней: если исходный файл не заканчивался символом "\n", эта
+// же особенность сохраняется — если только последняя строка не была
+// только что дописана через Set, а не прочитана из исходного файла.
+func (d *Document) Bytes() []byte {
+	var sb strings.Builder
+	for i, l := range d.lines {
+		switch l.Kind {
+		case KindBlank, KindComment:
+			sb.WriteString(l.Raw)
+		case KindKV:
+			if l.dirty {
+				sb.WriteString(l.ExportPrefix)
+				sb.WriteString(l.Key)
+				sb.WriteByte('=')
+				sb.WriteString(formatValue(l.Value))
+				sb.WriteString(l.Trailing)
+			} else {
+				sb.WriteString(l.Raw)
+			}
+		}
+		if i < len(d.lines)-1 || d.trailingNewline || l.fresh {
+			sb.WriteByte('\n')
+		}
+	}
+	return []byte(sb.String())
+}
+
+// Save записывает Document обратно в файл по правилам Bytes. Запись
+// атомарна (см. WriteFileAtomic): crash или конкурентный запуск не
+// может оставить файл наполовину записанным, а предыдущее содержимое
+// сохраняется в "<filePath>.bak".
+func (d *Document) Save(filePath string) error {
+	return WriteFileAtomic(filePath, d.Bytes(), true)
+}
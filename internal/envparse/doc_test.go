@@ -0,0 +1,112 @@
+package envparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocumentPreservesLayoutOnUnrelatedUpdate(t *testing.T) {
+	original := "# top comment\n" +
+		"FOO=bar\n" +
+		"\n" +
+		"BAR='single quoted'\n" +
+		"BAZ=\"double quoted\" # inline comment\n"
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	doc, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile: %v", err)
+	}
+
+	if added := doc.Set("FOO", "updated"); added {
+		t.Fatalf("Set(FOO) should update an existing key, not add one")
+	}
+
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	want := "# top comment\n" +
+		"FOO=updated\n" +
+		"\n" +
+		"BAR='single quoted'\n" +
+		"BAZ=\"double quoted\" # inline comment\n"
+
+	if string(got) != want {
+		t.Fatalf("unrelated lines were not preserved byte-for-byte\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDocumentPreservesMissingTrailingNewline(t *testing.T) {
+	original := "FOO=bar\nBAZ=qux"
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	doc, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile: %v", err)
+	}
+
+	if added := doc.Set("FOO", "updated"); added {
+		t.Fatalf("Set(FOO) should update an existing key, not add one")
+	}
+
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	want := "FOO=updated\nBAZ=qux"
+	if string(got) != want {
+		t.Fatalf("missing trailing newline was not preserved\ngot:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDocumentSetAppendsNewKeyBeforeTrailingBlankLine(t *testing.T) {
+	original := "FOO=bar\n\n"
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	doc, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatalf("LoadEnvFile: %v", err)
+	}
+
+	if added := doc.Set("NEW_KEY", "value"); !added {
+		t.Fatalf("Set(NEW_KEY) should report the key as newly added")
+	}
+
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read result: %v", err)
+	}
+
+	want := "FOO=bar\nNEW_KEY=value\n\n"
+	if string(got) != want {
+		t.Fatalf("new key was not appended before the trailing blank line\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
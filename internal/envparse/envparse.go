@@ -0,0 +1,163 @@
+// Package envparse реализует разбор и сериализацию файлов в формате .env,
+// совместимом с соглашениями популярных dotenv-инструментов: префикс
+// `export `, одинарные и двойные кавычки, экранирование спецсимволов,
+// инлайн-комментарии и многострочные значения. Входная точка для разбора
+// файла — LoadEnvFile/LoadEnvBytes (doc.go), возвращающие Document,
+// который сохраняет порядок строк и оформление при повторной записи;
+// ниже, в этом файле, — разделяемые ими низкоуровневые примитивы разбора
+// значения и сериализации (formatValue).
+package envparse
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readQuoted читает значение, заключённое в кавычку quote, накапливая
+// строки до тех пор, пока не встретится закрывающая кавычка. Если env не
+// nil, внутри значения обрабатываются экранирование и интерполяция
+// ${VAR}/$VAR (поведение двойных кавычек); иначе кавычка трактуется как
+// одинарная — содержимое копируется буквально. Возвращает также текст,
+// оставшийся в последней физической строке после закрывающей кавычки
+// (обычно инлайн-комментарий). Если raw не nil, в него добавляется
+// текст каждой дочитанной физической строки — используется вызывающей
+// стороной, которой нужно восстановить исходные строки файла побайтово.
+func readQuoted(buf string, scanner *bufio.Scanner, lineNum *int, quote byte, env map[string]string, raw *[]string) (value string, trailing string, err error) {
+	var sb strings.Builder
+	for {
+		i := 0
+		for i < len(buf) {
+			c := buf[i]
+
+			if env != nil && c == '\\' && i+1 < len(buf) {
+				switch buf[i+1] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 'r':
+					sb.WriteByte('\r')
+				case 't':
+					sb.WriteByte('\t')
+				case '"':
+					sb.WriteByte('"')
+				case '\\':
+					sb.WriteByte('\\')
+				case '$':
+					sb.WriteByte('$')
+				default:
+					sb.WriteByte(c)
+					sb.WriteByte(buf[i+1])
+				}
+				i += 2
+				continue
+			}
+
+			if c == quote {
+				return sb.String(), buf[i+1:], nil
+			}
+
+			if env != nil && c == '$' && i+1 < len(buf) {
+				name, n := readVarName(buf[i+1:])
+				if n > 0 {
+					sb.WriteString(lookupVar(name, env))
+					i += 1 + n
+					continue
+				}
+			}
+
+			sb.WriteByte(c)
+			i++
+		}
+
+		if !scanner.Scan() {
+			return "", "", fmt.Errorf("незакрытая кавычка %q", string(quote))
+		}
+		*lineNum++
+		sb.WriteByte('\n')
+		buf = scanner.Text()
+		if raw != nil {
+			*raw = append(*raw, buf)
+		}
+	}
+}
+
+// readVarName разбирает имя переменной после символа "$": либо
+// "{VAR}", либо голое VAR (буквы, цифры, подчёркивание). Возвращает имя
+// и число потреблённых после "$" символов; n == 0, если на этой позиции
+// имени переменной нет (например, "$" в конце значения).
+func readVarName(s string) (name string, n int) {
+	if len(s) == 0 {
+		return "", 0
+	}
+	if s[0] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", 0
+		}
+		return s[1:end], end + 1
+	}
+	if !isNameStart(s[0]) {
+		return "", 0
+	}
+	j := 1
+	for j < len(s) && isNameChar(s[j]) {
+		j++
+	}
+	return s[:j], j
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// lookupVar разрешает ссылку на переменную: сперва среди уже
+// разобранных ключей текущего файла, затем в окружении процесса.
+func lookupVar(name string, env map[string]string) string {
+	if v, ok := env[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+// needsQuoting сообщает, требует ли значение двойных кавычек при записи.
+func needsQuoting(v string) bool {
+	if v == "" {
+		return false
+	}
+	return strings.ContainsAny(v, " \t#=\"'\n\r")
+}
+
+// formatValue форматирует одно значение для записи в файл.
+func formatValue(v string) string {
+	if !needsQuoting(v) {
+		return v
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range v {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '$':
+			sb.WriteString(`\$`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
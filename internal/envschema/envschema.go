@@ -0,0 +1,125 @@
+// Package envschema определяет правила валидации и нормализации
+// ключей и значений .env-файла. Правила скрыты за интерфейсами
+// KeySchema и ValueSchema, так что вызывающий код не завязан на
+// конкретный регекс и может подключить более строгую или более мягкую
+// схему, не меняя логику запроса ввода.
+package envschema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeySchema приводит сырой пользовательский ввод ключа к каноничному
+// для данной схемы виду и проверяет результат.
+type KeySchema interface {
+	Format(raw string) (string, error)
+}
+
+// ValueSchema проверяет (и при необходимости нормализует) значение
+// переменной.
+type ValueSchema interface {
+	Validate(raw string) (string, error)
+}
+
+var posixKeyPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// DefaultKeySchema — POSIX-ish схема: ключ приводится к верхнему
+// регистру, пробелы заменяются на "_", и результат должен состоять из
+// заглавных латинских букв, цифр и "_", не начинаясь с цифры — так
+// валидируют имена переменных большинство популярных dotenv-инструментов.
+// Суффикс к ключу не дописывается, чтобы ключи вроде DATABASE_URL из
+// уже существующего .env оставались нетронутыми при импорте.
+type DefaultKeySchema struct{}
+
+func (DefaultKeySchema) Format(raw string) (string, error) {
+	key := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(raw), " ", "_"))
+	if !posixKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("ключ должен состоять из заглавных латинских букв, цифр и \"_\" и не начинаться с цифры")
+	}
+	return key, nil
+}
+
+var strictLatinKeyPattern = regexp.MustCompile(`^[A-Z_]+$`)
+
+// StrictKeySchema воспроизводит прежнее, более строгое поведение
+// инструмента: только латинские буквы и "_" (цифры запрещены), и ключ
+// всегда дописывается суффиксом "_KEY", если его ещё нет. Опт-ин для
+// тех, кто хочет сохранить старое соглашение об именовании.
+type StrictKeySchema struct{}
+
+func (StrictKeySchema) Format(raw string) (string, error) {
+	key := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(raw), " ", "_"))
+	if !strictLatinKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("ключ должен содержать только латинские буквы (без цифр и спецсимволов)")
+	}
+	if !strings.HasSuffix(key, "_KEY") {
+		key += "_KEY"
+	}
+	return key, nil
+}
+
+var looseKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// LooseKeySchema допускает смешанный регистр, а также "." и "-" в
+// дополнение к "_" — для импорта .env-файлов из экосистем (Node,
+// Docker Compose и т.п.), где такие имена встречаются на практике. Ключ
+// не приводится к верхнему регистру и не меняется иначе, кроме обрезки
+// пробелов по краям.
+type LooseKeySchema struct{}
+
+func (LooseKeySchema) Format(raw string) (string, error) {
+	key := strings.TrimSpace(raw)
+	if !looseKeyPattern.MatchString(key) {
+		return "", fmt.Errorf("ключ должен начинаться с буквы или \"_\" и содержать только латинские буквы, цифры, \"_\", \".\" и \"-\"")
+	}
+	return key, nil
+}
+
+// DefaultValueSchema допускает любое значение, кроме перевода строки
+// (сам формат .env однострочный; многострочные значения разбираются
+// envparse через кавычки, а не проверяются здесь) — включая Unicode, так
+// что URL с punycode, base64-токены и JSON-фрагменты проходят без
+// искусственных ограничений на алфавит.
+type DefaultValueSchema struct{}
+
+func (DefaultValueSchema) Validate(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if strings.ContainsAny(value, "\n\r") {
+		return "", fmt.Errorf("значение не должно содержать перевод строки")
+	}
+	return value, nil
+}
+
+var cyrillicPattern = regexp.MustCompile(`[а-яА-ЯёЁ]`)
+
+// StrictValueSchema воспроизводит прежнее поведение инструмента:
+// значение должно быть только на латинице, кириллица запрещена целиком.
+// Опт-ин для тех, кому важно гарантировать отсутствие случайно
+// вставленного нелатинского текста.
+type StrictValueSchema struct{}
+
+func (StrictValueSchema) Validate(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if cyrillicPattern.MatchString(value) {
+		return "", fmt.Errorf("значение должно быть только на латинице (без кириллицы)")
+	}
+	return value, nil
+}
+
+// LooseValueSchema требует только, чтобы значение не было пустым и не
+// содержало перевод строки — подходит для импорта значений из схем, где
+// сам факт непустого значения важнее его алфавита.
+type LooseValueSchema struct{}
+
+func (LooseValueSchema) Validate(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", fmt.Errorf("значение не должно быть пустым")
+	}
+	if strings.ContainsAny(value, "\n\r") {
+		return "", fmt.Errorf("значение не должно содержать перевод строки")
+	}
+	return value, nil
+}
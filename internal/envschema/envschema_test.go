@@ -0,0 +1,49 @@
+package envschema
+
+import "testing"
+
+func TestDefaultKeySchemaAllowsDigitsAndLeavesKeyUntouched(t *testing.T) {
+	got, err := DefaultKeySchema{}.Format("database_url")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "DATABASE_URL" {
+		t.Fatalf("got %q, want %q (no _KEY suffix should be appended)", got, "DATABASE_URL")
+	}
+
+	if _, err := (DefaultKeySchema{}).Format("api key 2"); err != nil {
+		t.Fatalf("Format should accept digits: %v", err)
+	}
+
+	if _, err := (DefaultKeySchema{}).Format("2fa secret"); err == nil {
+		t.Fatalf("Format should reject a key starting with a digit")
+	}
+}
+
+func TestStrictKeySchemaAppendsSuffix(t *testing.T) {
+	got, err := StrictKeySchema{}.Format("db password")
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != "DB_PASSWORD_KEY" {
+		t.Fatalf("got %q, want %q", got, "DB_PASSWORD_KEY")
+	}
+}
+
+func TestDefaultValueSchemaAllowsUnicode(t *testing.T) {
+	for _, value := range []string{
+		"xn--caf-dma.example.com",
+		"SGVsbG8rV29ybGQ9",
+		`{"token":"значение"}`,
+	} {
+		if _, err := (DefaultValueSchema{}).Validate(value); err != nil {
+			t.Fatalf("Validate(%q): %v", value, err)
+		}
+	}
+}
+
+func TestStrictValueSchemaRejectsCyrillic(t *testing.T) {
+	if _, err := (StrictValueSchema{}).Validate("привет"); err == nil {
+		t.Fatalf("Validate should reject Cyrillic under the strict schema")
+	}
+}
@@ -0,0 +1,31 @@
+// Package filelock предоставляет межпроцессную рекомендательную
+// блокировку файла: TryLock захватывает блокировку на sidecar-файл
+// (например, .env.lock), чтобы два параллельных запуска не могли
+// одновременно выполнить последовательность чтение-изменение-запись
+// над одним и тем же .env.
+package filelock
+
+import "fmt"
+
+// LockedError означает, что path уже заблокирован другим процессом.
+// Вызывающая сторона может распознать её через errors.As и повторить
+// попытку с задержкой (backoff), а не считать это фатальной ошибкой.
+type LockedError struct {
+	Path string
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("filelock: %s уже заблокирован другим процессом", e.Path)
+}
+
+// Lock — удерживаемая рекомендательная блокировка. Unlock освобождает
+// её и должен быть вызван ровно один раз, обычно через defer.
+type Lock struct {
+	path   string
+	unlock func() error
+}
+
+// Unlock освобождает блокировку.
+func (l *Lock) Unlock() error {
+	return l.unlock()
+}
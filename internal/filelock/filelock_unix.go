@@ -0,0 +1,35 @@
+//go:build unix
+
+package filelock
+
+import (
+	"os"
+	"syscall"
+)
+
+// TryLock пытается немедленно захватить эксклюзивную рекомендательную
+// блокировку path (создавая файл при необходимости) через flock(2). Если
+// блокировка уже занята другим процессом, возвращает *LockedError, не
+// дожидаясь её освобождения.
+func TryLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, &LockedError{Path: path}
+		}
+		return nil, err
+	}
+
+	return &Lock{
+		path: path,
+		unlock: func() error {
+			defer f.Close()
+			return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		},
+	}, nil
+}
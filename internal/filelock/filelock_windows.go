@@ -0,0 +1,40 @@
+//go:build windows
+
+package filelock
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// TryLock пытается немедленно захватить эксклюзивную рекомендательную
+// блокировку path (создавая файл при необходимости) через LockFileEx с
+// флагом LOCKFILE_FAIL_IMMEDIATELY. Если блокировка уже занята другим
+// процессом, возвращает *LockedError, не дожидаясь её освобождения.
+func TryLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := windows.Handle(f.Fd())
+	overlapped := new(windows.Overlapped)
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK | windows.LOCKFILE_FAIL_IMMEDIATELY)
+
+	if err := windows.LockFileEx(handle, flags, 0, 1, 0, overlapped); err != nil {
+		f.Close()
+		if err == windows.ERROR_LOCK_VIOLATION {
+			return nil, &LockedError{Path: path}
+		}
+		return nil, err
+	}
+
+	return &Lock{
+		path: path,
+		unlock: func() error {
+			defer f.Close()
+			return windows.UnlockFileEx(handle, 0, 1, 0, overlapped)
+		},
+	}, nil
+}
@@ -4,41 +4,42 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
-)
 
-// formatAndValidateKey обрабатывает ключ
-func formatAndValidateKey(input string) (string, error) {
-	input = strings.TrimSpace(input)
-	input = strings.ReplaceAll(input, " ", "_")
-	input = strings.ToUpper(input)
+	"golang.org/x/term"
 
-	match, _ := regexp.MatchString(`^[A-Z_]+$`, input)
-	if !match {
-		return "", fmt.Errorf("ключ должен содержать только латинские буквы (без цифр и спецсимволов)")
-	}
+	"github.com/Je-double-f/ForTest/internal/envcrypt"
+	"github.com/Je-double-f/ForTest/internal/envparse"
+	"github.com/Je-double-f/ForTest/internal/envschema"
+	"github.com/Je-double-f/ForTest/internal/filelock"
+)
 
-	if !strings.HasSuffix(input, "_KEY") {
-		input += "_KEY"
-	}
+// activeKeySchema и activeValueSchema определяют, какие ключи и
+// значения принимает интерактивный сценарий add. По умолчанию — POSIX-ish
+// схема envschema.DefaultKeySchema/DefaultValueSchema; замените их на
+// envschema.Strict*/Loose* (или собственную реализацию интерфейса), чтобы
+// подключить более строгое или более мягкое соглашение без изменения
+// остальной логики.
+var (
+	activeKeySchema   envschema.KeySchema   = envschema.DefaultKeySchema{}
+	activeValueSchema envschema.ValueSchema = envschema.DefaultValueSchema{}
+)
 
-	return input, nil
+// formatAndValidateKey приводит ключ к каноничному виду и проверяет его
+// через activeKeySchema.
+func formatAndValidateKey(input string) (string, error) {
+	return activeKeySchema.Format(input)
 }
 
-// validateValue проверяет, что значение не содержит кириллицу
+// validateValue проверяет значение через activeValueSchema.
 func validateValue(input string) (string, error) {
-	input = strings.TrimSpace(input)
-	match, _ := regexp.MatchString(`[а-яА-ЯёЁ]`, input)
-	if match {
-		return "", fmt.Errorf("значение должно быть только на латинице (без кириллицы)")
-	}
-	return input, nil
+	return activeValueSchema.Validate(input)
 }
 
-// promptValidInput — валидированный пользовательский ввод
-func promptValidInput(promptText string, validator func(string) (string, error)) string {
-	reader := bufio.NewReader(os.Stdin)
+// promptValidInput — валидированный пользовательский ввод. reader
+// передаётся вызывающей стороной и используется всеми подсказками одного
+// запуска команды (см. promptSecret).
+func promptValidInput(reader *bufio.Reader, promptText string, validator func(string) (string, error)) string {
 	for {
 		fmt.Print(promptText)
 		input, _ := reader.ReadString('\n')
@@ -52,42 +53,69 @@ func promptValidInput(promptText string, validator func(string) (string, error))
 	}
 }
 
-// readEnvFileToMap читает .env в карту ключ-значение
-func readEnvFileToMap(filePath string) (map[string]string, error) {
-	env := make(map[string]string)
+// promptSecret выводит promptText и читает значение без эха на
+// терминале (пароли, токены не должны оставаться в скроллбэке). Если
+// stdin не является TTY — например, значение подаётся через пайп в
+// тестах или CI — откатывается на обычное построчное чтение через
+// reader. reader должен быть одним и тем же на все подсказки одного
+// запуска команды: bufio.Reader буферизует вперёд, и заведение нового
+// reader на каждый вызов при пайпе теряет всё, что он успел дочитать
+// сверх текущей строки.
+func promptSecret(reader *bufio.Reader, promptText string) (string, error) {
+	fmt.Print(promptText)
 
-	file, err := os.OpenFile(filePath, os.O_RDONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return env, err
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(secret)), nil
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			env[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
 	}
-	return env, nil
+	return strings.TrimSpace(line), nil
 }
 
-// writeEnvMap сохраняет карту переменных в .env
-func writeEnvMap(filePath string, envMap map[string]string) error {
-	var newLines []string
-	for k, v := range envMap {
-		newLines = append(newLines, fmt.Sprintf("%s=%s", k, v))
+// promptSecretConfirm запрашивает секрет дважды (без эха) и требует,
+// чтобы оба ввода совпали — используется там, где опечатка означает
+// потерю доступа к данным (например, новая кодовая фраза шифрования).
+func promptSecretConfirm(reader *bufio.Reader, promptText, confirmText string) (string, error) {
+	value, err := promptSecret(reader, promptText)
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := promptSecret(reader, confirmText)
+	if err != nil {
+		return "", err
+	}
+	if value != confirmation {
+		return "", fmt.Errorf("введённые значения не совпадают")
+	}
+	return value, nil
+}
+
+// promptValidSecret — как promptValidInput, но без эха на терминале.
+func promptValidSecret(reader *bufio.Reader, promptText string, validator func(string) (string, error)) (string, error) {
+	for {
+		input, err := promptSecret(reader, promptText)
+		if err != nil {
+			return "", err
+		}
+		validated, verr := validator(input)
+		if verr != nil {
+			fmt.Println("❌ Ошибка:", verr)
+			continue
+		}
+		return validated, nil
 	}
-	return os.WriteFile(filePath, []byte(strings.Join(newLines, "\n")+"\n"), 0644)
 }
 
 // promptOverwriteConfirmation спрашивает подтверждение и даёт 3 попытки
-func promptOverwriteConfirmation(originalInput, currentValue string) bool {
-	reader := bufio.NewReader(os.Stdin)
+func promptOverwriteConfirmation(reader *bufio.Reader, originalInput, currentValue string) bool {
 	fmt.Printf("⚠️  Ключ \"%s\" уже существует. Вы хотите изменить значение? (yes/no): ", originalInput)
 	confirm, _ := reader.ReadString('\n')
 	confirm = strings.ToLower(strings.TrimSpace(confirm))
@@ -99,9 +127,11 @@ func promptOverwriteConfirmation(originalInput, currentValue string) bool {
 
 	const maxAttempts = 3
 	for attempts := 1; attempts <= maxAttempts; attempts++ {
-		fmt.Printf("Введите текущее значение для подтверждения (попытка %d из %d): ", attempts, maxAttempts)
-		entered, _ := reader.ReadString('\n')
-		entered = strings.TrimSpace(entered)
+		entered, err := promptSecret(reader, fmt.Sprintf("Введите текущее значение для подтверждения (попытка %d из %d): ", attempts, maxAttempts))
+		if err != nil {
+			fmt.Println("❌ Ошибка чтения:", err)
+			return false
+		}
 
 		if entered == currentValue {
 			return true
@@ -113,54 +143,155 @@ func promptOverwriteConfirmation(originalInput, currentValue string) bool {
 	return false
 }
 
-// AddOrUpdateEnvVarSecure безопасно добавляет или обновляет переменнуюe
-func AddOrUpdateEnvVarSecure(filePath, originalInput, key, value string) (added, updated bool, err error) {
-	envMap, err := readEnvFileToMap(filePath)
+// loadEnv читает filePath в envparse.Document, создавая пустой файл, если
+// его ещё нет (через envparse.LoadEnvFile) — это поведение нужно set/add,
+// которым разрешено породить новый .env. Если файл зашифрован envcrypt
+// (распознаётся по магической последовательности), запрашивает у reader
+// кодовую фразу без эха и расшифровывает содержимое в памяти —
+// незашифрованная копия на диск не попадает. Возвращённая passphrase
+// пуста, если файл не был зашифрован, и непуста, если был: saveEnv
+// использует её, чтобы сохранить файл зашифрованным.
+func loadEnv(reader *bufio.Reader, filePath string) (doc *envparse.Document, passphrase string, err error) {
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return false, false, err
+		if os.IsNotExist(err) {
+			doc, err = envparse.LoadEnvFile(filePath)
+			return doc, "", err
+		}
+		return nil, "", err
 	}
+	return decodeEnv(reader, data)
+}
 
-	currentValue, exists := envMap[key]
-	if exists {
-		if !promptOverwriteConfirmation(originalInput, currentValue) {
-			return false, false, nil
+// loadEnvReadOnly — как loadEnv, но не создаёт filePath, если его нет:
+// для команд, которым разрешено только читать или удалять существующие
+// данные (get/list/unset), отсутствие файла — это exitNotFound, а не
+// повод завести пустой .env.
+func loadEnvReadOnly(reader *bufio.Reader, filePath string) (doc *envparse.Document, passphrase string, err error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", newCLIError(exitNotFound, "файл %s не найден", filePath)
 		}
-		envMap[key] = value
-		err = writeEnvMap(filePath, envMap)
-		return false, true, err
+		return nil, "", err
 	}
-
-	envMap[key] = value
-	err = writeEnvMap(filePath, envMap)
-	return true, false, err
+	return decodeEnv(reader, data)
 }
 
-func main() {
-	fmt.Println("🔐 Безопасное добавление переменной в .env")
+// decodeEnv разбирает уже прочитанные байты filePath в envparse.Document,
+// прозрачно расшифровывая их через envcrypt, если нужно — общая часть
+// loadEnv и loadEnvReadOnly.
+func decodeEnv(reader *bufio.Reader, data []byte) (doc *envparse.Document, passphrase string, err error) {
+	if !envcrypt.IsEncrypted(data) {
+		doc, err = envparse.LoadEnvBytes(data)
+		return doc, "", err
+	}
 
-	// Получаем оригинальный ввод (до форматирования)
-	originalKeyInput := promptValidInput("Введите ключ переменной (например: db password): ", func(s string) (string, error) {
-		return s, nil
-	})
+	passphrase, err = promptSecret(reader, "Файл зашифрован. Введите кодовую фразу: ")
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := envcrypt.Open(data, passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+	doc, err = envparse.LoadEnvBytes(plaintext)
+	return doc, passphrase, err
+}
+
+// saveEnv записывает doc в filePath. Если passphrase непуста (файл был
+// зашифрован при чтении через loadEnv), результат запечатывается той же
+// кодовой фразой перед записью, так что обновление зашифрованного файла
+// никогда не оставляет открытый текст на диске.
+func saveEnv(doc *envparse.Document, filePath, passphrase string) error {
+	if passphrase == "" {
+		return doc.Save(filePath)
+	}
 
-	// Форматируем ключ отдельно
-	formattedKey, err := formatAndValidateKey(originalKeyInput)
+	sealed, err := envcrypt.Seal(doc.Bytes(), passphrase, envcrypt.DefaultKDFParams)
 	if err != nil {
-		fmt.Println("❌ Ошибка:", err)
-		return
+		return err
 	}
+	return envparse.WriteFileAtomic(filePath, sealed, true)
+}
 
-	value := promptValidInput("Введите значение переменной (только латиница): ", validateValue)
+// AddOrUpdateEnvVarSecure безопасно добавляет или обновляет переменную.
+// Файл читается и записывается через envparse.Document (при
+// необходимости — прозрачно расшифровывается и снова шифруется), так
+// что порядок строк, комментарии и пустые строки, а также оформление
+// всех незатронутых ключей остаются нетронутыми — меняется только
+// строка обновляемого (или дописывается строка нового) ключа.
+//
+// Вся последовательность чтение-изменение-запись выполняется под
+// эксклюзивной рекомендательной блокировкой sidecar-файла
+// "<filePath>.lock" (см. internal/filelock), чтобы два параллельных
+// запуска не могли одновременно обновить один и тот же .env. Если файл
+// уже заблокирован другим процессом, возвращается *filelock.LockedError
+// — вызывающая сторона может распознать её через errors.As и повторить
+// попытку с задержкой, а не считать это фатальной ошибкой. reader —
+// общий на весь запуск команды bufio.Reader(os.Stdin) (см. promptSecret),
+// через него могут быть запрошены и кодовая фраза зашифрованного файла, и
+// подтверждение перезаписи.
+func AddOrUpdateEnvVarSecure(reader *bufio.Reader, filePath, originalInput, key, value string) (added, updated bool, err error) {
+	lock, err := filelock.TryLock(filePath + ".lock")
+	if err != nil {
+		return false, false, err
+	}
+	defer lock.Unlock()
 
-	added, updated, err := AddOrUpdateEnvVarSecure(".env", originalKeyInput, formattedKey, value)
+	doc, passphrase, err := loadEnv(reader, filePath)
 	if err != nil {
-		fmt.Println("❌ Ошибка при обновлении:", err)
+		return false, false, err
+	}
+
+	currentValue, exists := doc.Get(key)
+	if exists {
+		if !promptOverwriteConfirmation(reader, originalInput, currentValue) {
+			return false, false, nil
+		}
+		doc.Set(key, value)
+		err = saveEnv(doc, filePath, passphrase)
+		return false, true, err
+	}
+
+	doc.Set(key, value)
+	err = saveEnv(doc, filePath, passphrase)
+	return true, false, err
+}
+
+// main — диспетчер "envtool": без аргументов или с "add" запускает
+// интерактивный сценарий с эмодзи-подсказками на русском; остальные
+// подкоманды (set/get/unset/list/import/diff, а также старые
+// --encrypt/--decrypt/--rekey) неинтерактивны и рассчитаны на CI и
+// shell-пайплайны, поэтому ничего не печатают, кроме запрошенных данных
+// и сообщений об ошибках, и завершаются кодом, который runCLICommand
+// извлекает из *cliError.
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "add" {
+		runInteractiveAdd()
 		return
 	}
 
-	if added {
-		fmt.Println("✅ Переменная успешно добавлена.")
-	} else if updated {
-		fmt.Println("✅ Переменная успешно обновлена.")
+	switch cmd := os.Args[1]; cmd {
+	case "--encrypt", "--decrypt", "--rekey":
+		if err := runCryptoSubcommand(cmd, ".env"); err != nil {
+			fmt.Println("❌ Ошибка:", err)
+			os.Exit(exitGenericError)
+		}
+	case "set":
+		runCLICommand(cmdSet(os.Args[2:]))
+	case "get":
+		runCLICommand(cmdGet(os.Args[2:]))
+	case "unset":
+		runCLICommand(cmdUnset(os.Args[2:]))
+	case "list":
+		runCLICommand(cmdList(os.Args[2:]))
+	case "import":
+		runCLICommand(cmdImport(os.Args[2:]))
+	case "diff":
+		runCLICommand(cmdDiff(os.Args[2:]))
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестная команда %q (допустимо: add, set, get, unset, list, import, diff)\n", cmd)
+		os.Exit(exitGenericError)
 	}
 }